@@ -0,0 +1,129 @@
+package blaster
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// BatchWorker is implemented by workers that can process several payloads
+// in one round trip (a Kafka producer, a bulk HTTP endpoint, a Cassandra
+// batch statement, ...). Responses are returned in the same order as the
+// payloads that were passed in.
+type BatchWorker interface {
+	SendBatch(ctx context.Context, payloads []map[string]interface{}) (responses []map[string]interface{}, err error)
+}
+
+// RegisterBatchWorkerType registers a BatchWorker constructor under key,
+// mirroring RegisterWorkerType. When config.BatchSize is greater than zero,
+// start runs startBatcherLoop in place of startWorkers, so the workDef
+// records startMainLoop sends on workerChannel are batched instead of
+// dispatched to individual Worker instances one at a time.
+func (b *Blaster) RegisterBatchWorkerType(key string, workerFunc func() BatchWorker) {
+	b.batchWorkerTypes[key] = workerFunc
+}
+
+// startBatcherLoop coalesces incoming workDef records from workerChannel
+// into batches, flushing a batch as soon as it reaches config.BatchSize
+// items, and flushing every partial batch each time a periodic
+// config.BatchMaxWait timer fires, so no item waits longer than
+// BatchMaxWait. The timer is not tied to when a batch's first item
+// arrived. Each flushed batch is dispatched to the
+// registered BatchWorker for its worker type in one SendBatch call.
+//
+// It runs synchronously until ctx is done, its workersFinishedChannel
+// fires, or workerChannel is closed; it is managed as a Service via
+// newServiceFunc rather than spawning its own goroutine.
+func (b *Blaster) startBatcherLoop(ctx context.Context) {
+
+	batches := make(map[string][]workDef)
+	timer := time.NewTimer(b.config.BatchMaxWait)
+	defer timer.Stop()
+
+	flush := func(workerType string) {
+		batch := batches[workerType]
+		if len(batch) == 0 {
+			return
+		}
+		delete(batches, workerType)
+		b.workerWait.Add(1)
+		go b.dispatchBatch(ctx, workerType, batch)
+	}
+
+	flushAll := func() {
+		for workerType := range batches {
+			flush(workerType)
+		}
+	}
+
+	for {
+		select {
+		case w, ok := <-b.workerChannel:
+			if !ok {
+				flushAll()
+				return
+			}
+
+			batches[w.WorkerType] = append(batches[w.WorkerType], w)
+			b.metrics.incBatchQueued(w.WorkerType)
+
+			if len(batches[w.WorkerType]) >= b.config.BatchSize {
+				flush(w.WorkerType)
+			}
+
+		case <-timer.C:
+			flushAll()
+			timer.Reset(b.config.BatchMaxWait)
+
+		case <-b.workersFinishedChannel:
+			flushAll()
+			return
+
+		case <-ctx.Done():
+			flushAll()
+			return
+		}
+	}
+}
+
+// dispatchBatch sends a batch of payloads to the BatchWorker registered for
+// workerType and fans the per-item responses/errors back out through
+// logRecord, exactly as startWorkers does for single sends, so downstream
+// logging and metrics don't need to know batching happened. It's run in
+// its own goroutine by flush, which holds the matching workerWait.Add(1),
+// so that dispatching one batch never blocks startBatcherLoop from
+// continuing to coalesce the next one.
+func (b *Blaster) dispatchBatch(ctx context.Context, workerType string, batch []workDef) {
+
+	defer b.workerWait.Done()
+
+	newBatchWorker, ok := b.batchWorkerTypes[workerType]
+	if !ok {
+		b.errorChannel <- fmt.Errorf("no batch worker registered for type %q", workerType)
+		return
+	}
+	worker := newBatchWorker()
+
+	payloads := make([]map[string]interface{}, len(batch))
+	for i, w := range batch {
+		payloads[i] = w.Payload
+	}
+
+	start := time.Now()
+	responses, err := worker.SendBatch(ctx, payloads)
+	elapsed := time.Since(start)
+
+	b.metrics.recordBatch(workerType, len(batch), elapsed, err)
+
+	for i, w := range batch {
+		record := logRecord{
+			Input: w.Payload,
+		}
+		if err != nil {
+			record.Error = err
+		} else if i < len(responses) {
+			record.Output = responses[i]
+		}
+		b.emitLogRecord(record)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"sync"
+	"syscall"
 
 	"time"
 
@@ -15,14 +16,44 @@ import (
 	"github.com/leemcloughlin/gofarmhash"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const DEBUG = false
 
+// cancelBox holds a context.CancelFunc that's assigned once start()
+// derives the dispatch context, but must be safely callable from the
+// signal-handling goroutine spawned in New, which can fire before that
+// assignment happens (e.g. a signal arriving before Start is called).
+type cancelBox struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func (c *cancelBox) set(cancel context.CancelFunc) {
+	c.mu.Lock()
+	c.cancel = cancel
+	c.mu.Unlock()
+}
+
+func (c *cancelBox) call() {
+	c.mu.Lock()
+	cancel := c.cancel
+	c.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
 type Blaster struct {
-	config          *configDef
-	viper           *viper.Viper
-	rate            float64
+	config *configDef
+	viper  *viper.Viper
+	// rateBits is the current send rate, stored as atomic float64 bits
+	// (see rate()/setRate() in mainloop.go) since it's written from
+	// startTickerLoop and read concurrently by startRateControlLoop,
+	// metricsDef.Snapshot and printStatus.
+	rateBits        uint64
 	softTimeout     time.Duration
 	hardTimeout     time.Duration
 	skip            map[farmhash.Uint128]struct{}
@@ -44,14 +75,29 @@ type Blaster struct {
 	changeRateChannel      chan float64
 	signalChannel          chan os.Signal
 
-	mainWait   *sync.WaitGroup
 	workerWait *sync.WaitGroup
 
-	workerTypes map[string]func() Worker
+	workerTypes      map[string]func() Worker
+	batchWorkerTypes map[string]func() BatchWorker
 
 	errorsIgnored uint64
 	metrics       *metricsDef
 	err           error
+
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	instr          *instrumentation
+
+	dedupeGroup   *Group
+	DedupeKeyFunc func(workerType string, payload map[string]interface{}) farmhash.Uint128
+
+	statusServer *statusServer
+
+	lifecycle        *lifecycleManager
+	shutdownComplete chan struct{}
+	dispatchCancel   *cancelBox
+
+	RateController RateController
 }
 
 type DataReader interface {
@@ -68,9 +114,9 @@ func New(ctx context.Context, cancel context.CancelFunc) *Blaster {
 	b := &Blaster{
 		viper:                  viper.New(),
 		cancel:                 cancel,
-		mainWait:               new(sync.WaitGroup),
 		workerWait:             new(sync.WaitGroup),
 		workerTypes:            make(map[string]func() Worker),
+		batchWorkerTypes:       make(map[string]func() BatchWorker),
 		skip:                   make(map[farmhash.Uint128]struct{}),
 		dataFinishedChannel:    make(chan struct{}),
 		workersFinishedChannel: make(chan struct{}),
@@ -79,25 +125,81 @@ func New(ctx context.Context, cancel context.CancelFunc) *Blaster {
 		logChannel:             make(chan logRecord),
 		mainChannel:            make(chan struct{}),
 		workerChannel:          make(chan workDef),
+		dedupeGroup:            NewGroup(),
+		lifecycle:              newLifecycleManager(),
+		shutdownComplete:       make(chan struct{}),
+		dispatchCancel:         &cancelBox{},
 	}
 	b.metrics = newMetricsDef(b)
 
-	// trap Ctrl+C and call cancel on the context
+	// Trap Ctrl+C, SIGTERM and SIGQUIT. The first signal stops new dispatch
+	// (startTickerLoop/startMainLoop, via dispatchCancel) but leaves ctx
+	// uncancelled, so in-flight Worker.Send calls get up to
+	// config.ShutdownTimeout to finish on their own. A second signal, or
+	// the grace period elapsing, cancels ctx itself and forces an exit.
 	b.signalChannel = make(chan os.Signal, 1)
-	signal.Notify(b.signalChannel, os.Interrupt)
+	signal.Notify(b.signalChannel, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
 	go func() {
 		select {
 		case <-b.signalChannel:
-			b.cancel()
 		case <-ctx.Done():
+			return
+		}
+
+		fmt.Fprintln(os.Stderr, "shutdown signal received, draining in-flight workers...")
+		b.dispatchCancel.call()
+		go b.forceShutdownAfterGrace()
+
+		select {
+		case <-b.signalChannel:
+			fmt.Fprintln(os.Stderr, "second signal received, forcing immediate shutdown")
+			b.cancel()
+			os.Exit(1)
+		case <-b.shutdownComplete:
 		}
 	}()
 
 	return b
 }
 
+// forceExitGrace bounds how long forceShutdownAfterGrace waits after
+// cancelling ctx before giving up on a graceful unwind and exiting the
+// process outright.
+const forceExitGrace = 5 * time.Second
+
+// forceShutdownAfterGrace gives the drain started by a first shutdown
+// signal up to config.ShutdownTimeout to finish on its own. If it doesn't,
+// it cancels ctx (aborting any still-running Worker.Send calls) and gives
+// the process forceExitGrace to unwind before forcing an exit, so a stuck
+// worker can't hang the process forever after an operator has already
+// asked it to stop.
+func (b *Blaster) forceShutdownAfterGrace() {
+	timeout := b.config.ShutdownTimeout
+	if timeout > 0 {
+		select {
+		case <-time.After(timeout):
+		case <-b.shutdownComplete:
+			return
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "shutdown-timeout exceeded waiting for workers to drain, cancelling in-flight work")
+	b.cancel()
+
+	select {
+	case <-time.After(forceExitGrace):
+		fmt.Fprintln(os.Stderr, "workers did not unwind after cancellation, forcing exit")
+		os.Exit(1)
+	case <-b.shutdownComplete:
+	}
+}
+
+// Exit restores the default signal behavior for Interrupt/SIGTERM/SIGQUIT
+// (via signal.Reset rather than signal.Stop) so that an embedder running
+// Blaster as one component of a larger daemon gets its own signal
+// handling back once Blaster is done with it, then cancels the context.
 func (b *Blaster) Exit() {
-	signal.Stop(b.signalChannel)
+	signal.Reset(os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
 	b.cancel()
 }
 
@@ -133,20 +235,69 @@ func (b *Blaster) Start(ctx context.Context) error {
 
 func (b *Blaster) start(ctx context.Context) error {
 
-	b.metrics.addSegment(b.rate)
+	if err := b.setupInstrumentation(); err != nil {
+		return err
+	}
 
-	b.startTickerLoop(ctx)
-	b.startMainLoop(ctx)
-	b.startErrorLoop(ctx)
-	b.startWorkers(ctx)
-	b.startLogLoop(ctx)
-	b.startStatusLoop(ctx)
-	b.startRateLoop(ctx)
+	b.metrics.addSegment(b.rate())
+
+	// dispatchCtx governs only the two loops that produce new work
+	// (startTickerLoop/startMainLoop). The first shutdown signal cancels
+	// it via b.dispatchCancel, stopping new dispatch while leaving ctx
+	// itself uncancelled so in-flight Worker.Send calls, which run on ctx
+	// all the way down through startWorkers/handleWork, keep running until
+	// the drain finishes or a second signal cancels ctx.
+	dispatchCtx, cancelDispatch := context.WithCancel(ctx)
+	b.dispatchCancel.set(cancelDispatch)
+	defer cancelDispatch()
+
+	// Every start*Loop goroutine is managed as a Service: lifecycle.register
+	// starts it immediately (so call order here still matches the old
+	// ad-hoc-WaitGroup ordering) and stopAll/waitAll below give each one a
+	// uniform, bounded shutdown instead of relying solely on channel closes.
+	dispatchLoops := []func(context.Context){
+		b.startTickerLoop,
+		b.startMainLoop,
+	}
+	for _, loop := range dispatchLoops {
+		if err := b.lifecycle.register(dispatchCtx, newServiceFunc(loop)); err != nil {
+			return err
+		}
+	}
+
+	loops := []func(context.Context){
+		b.startErrorLoop,
+	}
+	if b.config.BatchSize > 0 {
+		loops = append(loops, b.startBatcherLoop)
+	} else {
+		loops = append(loops, b.startWorkers)
+	}
+	loops = append(loops, b.startLogLoop, b.startStatusLoop, b.startRateLoop)
+
+	for _, loop := range loops {
+		if err := b.lifecycle.register(ctx, newServiceFunc(loop)); err != nil {
+			return err
+		}
+	}
+
+	if b.config.AdaptiveRate {
+		if err := b.lifecycle.register(ctx, newServiceFunc(b.startRateControlLoop)); err != nil {
+			return err
+		}
+	}
+
+	if b.config.StatusAddr != "" {
+		if err := b.lifecycle.register(ctx, newStatusServer(b, b.config.StatusHistorySize)); err != nil {
+			return err
+		}
+	}
 
 	b.printRatePrompt()
 
-	// wait for cancel or finished
+	// wait for the dispatch-stop signal, cancel or finished
 	select {
+	case <-dispatchCtx.Done():
 	case <-ctx.Done():
 	case <-b.dataFinishedChannel:
 	}
@@ -159,9 +310,21 @@ func (b *Blaster) start(ctx context.Context) error {
 	close(b.workersFinishedChannel)
 
 	fmt.Fprintln(b.out, "Waiting for processes to finish...")
-	b.mainWait.Wait()
+	shutdownTimeout := b.config.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = 30 * time.Second
+	}
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	b.lifecycle.stopAll(shutdownCtx)
+	b.lifecycle.waitAll()
+	if err := b.shutdownInstrumentation(shutdownCtx); err != nil {
+		fmt.Fprintf(b.out, "failed to shut down OTel providers: %v\n", err)
+	}
+	cancelShutdown()
 	fmt.Fprintln(b.out, "All processes finished.")
 
+	close(b.shutdownComplete)
+
 	if b.err != nil {
 		fmt.Fprintln(b.out, "")
 		errorsIgnored := atomic.LoadUint64(&b.errorsIgnored)
@@ -207,4 +370,4 @@ func (t *ThreadSafeWriter) Write(p []byte) (n int, err error) {
 	t.m.Lock()
 	defer t.m.Unlock()
 	return t.w.Write(p)
-}
\ No newline at end of file
+}
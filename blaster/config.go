@@ -0,0 +1,113 @@
+package blaster
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// configDef is populated from flags (and, if present, a config file picked
+// up by viper) in loadConfigViper. Every Blaster subsystem reads its
+// settings from here rather than taking its own constructor arguments, so
+// `--config` can always be used to see the full set in one place.
+type configDef struct {
+	Data   string  `mapstructure:"data"`
+	Log    string  `mapstructure:"log"`
+	Rate   float64 `mapstructure:"rate"`
+	Worker string  `mapstructure:"worker"`
+
+	// OpenTelemetry tracing/metrics (chunk0-1). Left empty, OTel stays a
+	// no-op so instrumentation costs nothing for users who don't opt in.
+	OTLPEndpoint           string            `mapstructure:"otlp-endpoint"`
+	OTLPSamplingRatio      float64           `mapstructure:"otlp-sampling-ratio"`
+	OTLPResourceAttributes map[string]string `mapstructure:"otlp-resource-attributes"`
+
+	// Batching (chunk0-2). BatchSize of 0 (the default) keeps the default
+	// per-item startWorkers path; setting it routes workDef records to
+	// startBatcherLoop instead.
+	BatchSize    int           `mapstructure:"batch-size"`
+	BatchMaxWait time.Duration `mapstructure:"batch-max-wait"`
+
+	// Dedupe (chunk0-3). Routes Worker.Send through dedupeGroup; requires
+	// Blaster.DedupeKeyFunc to also be set, otherwise dedupedSend is a
+	// pass-through.
+	Dedupe bool `mapstructure:"dedupe"`
+
+	// Status server (chunk0-4). StatusAddr of "" (the default) keeps the
+	// server off entirely.
+	StatusAddr        string `mapstructure:"status-addr"`
+	StatusHistorySize int    `mapstructure:"status-history-size"`
+
+	// ShutdownTimeout (chunk0-5) bounds the graceful drain started by the
+	// first shutdown signal before a second signal, or a forced timeout,
+	// ends things immediately.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown-timeout"`
+
+	// Adaptive rate control (chunk0-6). Disabled unless AdaptiveRate is set;
+	// the rest configure the default AIMD RateController.
+	AdaptiveRate              bool          `mapstructure:"adaptive-rate"`
+	RateControlMin            float64       `mapstructure:"rate-control-min"`
+	RateControlMax            float64       `mapstructure:"rate-control-max"`
+	RateControlIncrease       float64       `mapstructure:"rate-control-increase"`
+	RateControlBackoffFactor  float64       `mapstructure:"rate-control-backoff-factor"`
+	RateControlLatencyTarget  time.Duration `mapstructure:"rate-control-latency-target"`
+	RateControlErrorThreshold float64       `mapstructure:"rate-control-error-threshold"`
+}
+
+// loadConfigViper defines the flag set, binds it into b.viper, and
+// unmarshals the result into b.config. It's called once, from Start,
+// before any data file or log file is opened.
+func (b *Blaster) loadConfigViper() error {
+
+	flags := pflag.NewFlagSet("blast", pflag.ContinueOnError)
+
+	flags.String("data", "", "Path to the CSV data file to blast")
+	flags.String("log", "", "Path to write the CSV log of results")
+	flags.Float64("rate", 1, "Initial requests-per-second rate")
+	flags.String("worker", "", "Registered worker type to send each data record to")
+
+	flags.String("otlp-endpoint", "", "OTLP gRPC endpoint to export traces/metrics to (tracing disabled if empty)")
+	flags.Float64("otlp-sampling-ratio", 0, "Trace sampling ratio in (0,1]; 0 means always-on")
+	flags.StringToString("otlp-resource-attributes", nil, "Extra OTel resource attributes, key=value,key=value")
+
+	flags.Int("batch-size", 0, "Batch size for BatchWorker dispatch; 0 disables batching")
+	flags.Duration("batch-max-wait", time.Second, "Max time a partial batch waits before flushing")
+
+	flags.Bool("dedupe", false, "Deduplicate concurrent in-flight sends for the same logical payload (requires Blaster.DedupeKeyFunc)")
+
+	flags.String("status-addr", "", "Listen address for the opt-in HTTP status server (/metrics, /stream, /rate); empty disables it")
+	flags.Int("status-history-size", 100, "Number of status/log frames the status server replays to a new /stream subscriber")
+
+	flags.Duration("shutdown-timeout", 30*time.Second, "Grace period for in-flight workers to drain after the first shutdown signal")
+
+	flags.Bool("adaptive-rate", false, "Automatically adjust rate based on observed latency/errors (AIMD)")
+	flags.Float64("rate-control-min", 1, "Minimum rate the adaptive controller will settle at")
+	flags.Float64("rate-control-max", 0, "Maximum rate the adaptive controller will settle at; 0 means unbounded")
+	flags.Float64("rate-control-increase", 1, "Additive rate increase per control tick while within budget")
+	flags.Float64("rate-control-backoff-factor", 0.7, "Multiplicative rate decrease applied when over budget")
+	flags.Duration("rate-control-latency-target", 200*time.Millisecond, "p95 latency budget for the adaptive controller")
+	flags.Float64("rate-control-error-threshold", 0.01, "Error ratio budget for the adaptive controller")
+
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		return err
+	}
+
+	if err := b.viper.BindPFlags(flags); err != nil {
+		return err
+	}
+
+	b.viper.SetConfigName("blast")
+	b.viper.AddConfigPath(".")
+	// A config file is optional; flags/defaults are enough on their own.
+	_ = b.viper.ReadInConfig()
+
+	b.config = &configDef{}
+	if err := b.viper.Unmarshal(b.config); err != nil {
+		return err
+	}
+
+	b.setRate(b.config.Rate)
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package blaster
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+)
+
+// openDataFile opens config.Data as a CSV file and returns its header
+// row. *csv.Reader already satisfies DataReader (Read() ([]string, error))
+// so it's used directly rather than through an adapter.
+func (b *Blaster) openDataFile(ctx context.Context) ([]string, error) {
+	f, err := os.Open(b.config.Data)
+	if err != nil {
+		return nil, err
+	}
+	b.dataCloser = f
+
+	reader := csv.NewReader(f)
+	b.dataReader = reader
+
+	headers, err := reader.Read()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return headers, nil
+}
+
+// closeDataFile closes the data file opened by openDataFile, if any.
+func (b *Blaster) closeDataFile() {
+	if b.dataCloser != nil {
+		_ = b.dataCloser.Close()
+	}
+}
@@ -0,0 +1,154 @@
+package blaster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	farmhash "github.com/leemcloughlin/gofarmhash"
+)
+
+// call is the in-flight state shared by every caller of Group.Do that
+// arrives with the same key while the first caller's fn is still running.
+type call struct {
+	val  interface{}
+	err  error
+	done chan struct{}
+
+	cancel context.CancelFunc
+
+	waiters int // guarded by Group.mu
+}
+
+// Group deduplicates concurrent calls keyed by a farmhash.Uint128, so that
+// identical in-flight requests share a single underlying call and all
+// waiters observe the same result. It is the blaster analogue of
+// golang.org/x/sync/singleflight, adapted to cancel the shared call once
+// every waiter that asked for it has gone away rather than only once.
+type Group struct {
+	mu sync.Mutex
+	m  map[farmhash.Uint128]*call
+}
+
+// NewGroup returns an empty Group ready for use.
+func NewGroup() *Group {
+	return &Group{
+		m: make(map[farmhash.Uint128]*call),
+	}
+}
+
+// Do executes and returns the result of fn, making sure that only one
+// execution is in flight for a given key at a time. If a duplicate call
+// comes in while one is already running, the duplicate caller waits for
+// the original to complete and receives the same result.
+//
+// Each caller's ctx governs only its own wait: if ctx is cancelled before
+// the shared call finishes, Do returns ctx.Err() without affecting other
+// waiters. Only when every waiter for a key has gone away is the shared
+// call's own context cancelled, so a straggler arriving just after the
+// last waiter left starts a fresh call rather than reusing a cancelled one.
+func (g *Group) Do(ctx context.Context, key farmhash.Uint128, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+
+	g.mu.Lock()
+	if c, ok := g.m[key]; ok {
+		c.waiters++
+		g.mu.Unlock()
+
+		return g.wait(ctx, key, c)
+	}
+
+	callCtx, cancel := context.WithCancel(detach(ctx))
+	c := &call{
+		done:    make(chan struct{}),
+		cancel:  cancel,
+		waiters: 1,
+	}
+	g.m[key] = c
+	g.mu.Unlock()
+
+	go func() {
+		c.val, c.err = fn(callCtx)
+		close(c.done)
+
+		g.mu.Lock()
+		if g.m[key] == c {
+			delete(g.m, key)
+		}
+		g.mu.Unlock()
+	}()
+
+	return g.wait(ctx, key, c)
+}
+
+// wait blocks until either the shared call completes or the caller's own
+// ctx is cancelled, releasing this waiter's share of c in either case.
+func (g *Group) wait(ctx context.Context, key farmhash.Uint128, c *call) (interface{}, error) {
+	defer g.release(key, c)
+
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// release drops this caller's waiter count on c. Once nobody is left
+// waiting, it removes c from the group's map in the same g.mu critical
+// section as the decrement, then cancels its context after unlocking, so a
+// concurrent Do for the same key can never observe a zero-but-not-yet-deleted
+// waiters count and attach to a call that's about to be cancelled out from
+// under it.
+func (g *Group) release(key farmhash.Uint128, c *call) {
+	g.mu.Lock()
+	c.waiters--
+	last := c.waiters == 0
+	if last && g.m[key] == c {
+		delete(g.m, key)
+	}
+	g.mu.Unlock()
+
+	if last {
+		c.cancel()
+	}
+}
+
+// detach returns a context that carries ctx's values but is not cancelled
+// when ctx is, so the shared call survives the first caller walking away
+// while other waiters are still attached.
+func detach(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
+
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}                   { return nil }
+func (detachedContext) Err() error                              { return nil }
+
+// dedupedSend routes a Worker.Send through b.dedupeGroup when dedupe is
+// enabled (config.Dedupe and a DedupeKeyFunc are both set), so that
+// concurrent requests for the same logical payload share one in-flight
+// Send call. It is the integration point called from handleWork in
+// place of a direct worker.Send(ctx, payload).
+func (b *Blaster) dedupedSend(ctx context.Context, workerType string, payload map[string]interface{}, send func(context.Context) (map[string]interface{}, error)) (map[string]interface{}, error) {
+
+	if !b.config.Dedupe || b.DedupeKeyFunc == nil {
+		return send(ctx)
+	}
+
+	key := b.DedupeKeyFunc(workerType, payload)
+
+	result, err := b.dedupeGroup.Do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return send(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+	return result.(map[string]interface{}), nil
+}
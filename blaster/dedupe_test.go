@@ -0,0 +1,125 @@
+package blaster
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	farmhash "github.com/leemcloughlin/gofarmhash"
+)
+
+func TestGroupDoDeduplicatesConcurrentCalls(t *testing.T) {
+	g := NewGroup()
+	key := farmhash.Hash128([]byte("same-key"))
+
+	started := make(chan struct{})
+	secondAttached := make(chan struct{})
+	release := make(chan struct{})
+
+	var calls int64
+	fn := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		close(started)
+		<-release
+		return "result", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 2)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		val, err := g.Do(context.Background(), key, fn)
+		if err != nil {
+			t.Errorf("Do: %v", err)
+		}
+		results[0] = val
+	}()
+
+	<-started
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		close(secondAttached)
+		val, err := g.Do(context.Background(), key, fn)
+		if err != nil {
+			t.Errorf("Do: %v", err)
+		}
+		results[1] = val
+	}()
+
+	<-secondAttached
+	// Give the second Do a moment to reach g.m[key] and attach as a
+	// waiter before the first call is allowed to finish.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt64(&calls); got != 1 {
+		t.Fatalf("fn called %d times, want 1", got)
+	}
+	if results[0] != "result" || results[1] != "result" {
+		t.Fatalf("results = %v, want both %q", results, "result")
+	}
+}
+
+func TestGroupReleaseAllowsFreshCallAfterLastWaiter(t *testing.T) {
+	g := NewGroup()
+	key := farmhash.Hash128([]byte("fresh-key"))
+
+	var first int64
+	val, err := g.Do(context.Background(), key, func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt64(&first, 1)
+		return "first", nil
+	})
+	if err != nil || val != "first" {
+		t.Fatalf("first Do = %v, %v", val, err)
+	}
+
+	// Once the last (only) waiter has released, the key must be free for
+	// a new call rather than reusing the now-cancelled shared call.
+	var second int64
+	val, err = g.Do(context.Background(), key, func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt64(&second, 1)
+		return "second", nil
+	})
+	if err != nil {
+		t.Fatalf("second Do: %v", err)
+	}
+	if val != "second" {
+		t.Fatalf("second Do = %v, want %q", val, "second")
+	}
+	if atomic.LoadInt64(&second) != 1 {
+		t.Fatalf("second fn called %d times, want 1", second)
+	}
+}
+
+// TestGroupReleaseRace exercises the atomic waiters-decrement/map-delete
+// fix under -race: a joiner attaching to a call at the exact moment the
+// last existing waiter releases it must never see a spurious
+// context.Canceled from a call it had a live waiter count on.
+func TestGroupReleaseRace(t *testing.T) {
+	g := NewGroup()
+	key := farmhash.Hash128([]byte("race-key"))
+
+	fn := func(ctx context.Context) (interface{}, error) {
+		time.Sleep(time.Millisecond)
+		return "ok", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := g.Do(context.Background(), key, fn); err != nil {
+				t.Errorf("Do: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
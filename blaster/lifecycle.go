@@ -0,0 +1,138 @@
+package blaster
+
+import (
+	"context"
+	"sync"
+)
+
+// Service is the uniform lifecycle every long-running piece of Blaster
+// (the ticker/main/error/log/status/rate loops, the worker or batcher loop,
+// the rate controller and the status server) is managed through, in place of the
+// ad-hoc WaitGroups and channel closes each loop previously rolled on its
+// own. Start must return once the service has begun running; Wait blocks
+// until it has fully stopped; Stop requests an orderly shutdown (honoring
+// ctx's deadline) and IsRunning reports current state for diagnostics and
+// tests.
+type Service interface {
+	Start(ctx context.Context) error
+	Wait()
+	Stop(ctx context.Context) error
+	IsRunning() bool
+}
+
+// serviceFunc adapts a fire-and-forget "run until ctx is done" function
+// into a Service, for loops that don't (yet) have their own orderly stop
+// signal beyond context cancellation.
+type serviceFunc struct {
+	run func(ctx context.Context)
+
+	mu      sync.Mutex
+	running bool
+	done    chan struct{}
+	cancel  context.CancelFunc
+}
+
+func newServiceFunc(run func(ctx context.Context)) *serviceFunc {
+	return &serviceFunc{run: run}
+}
+
+func (s *serviceFunc) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	s.mu.Lock()
+	s.cancel = cancel
+	s.running = true
+	s.done = make(chan struct{})
+	s.mu.Unlock()
+
+	go func() {
+		defer close(s.done)
+		defer func() {
+			s.mu.Lock()
+			s.running = false
+			s.mu.Unlock()
+		}()
+		s.run(ctx)
+	}()
+
+	return nil
+}
+
+func (s *serviceFunc) Wait() {
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+func (s *serviceFunc) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *serviceFunc) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// lifecycleManager starts services in registration order and stops them
+// in reverse, giving each one up to its own Stop deadline before moving
+// on, so a slow subsystem delays but cannot block the rest of shutdown
+// forever.
+type lifecycleManager struct {
+	mu       sync.Mutex
+	services []Service
+}
+
+func newLifecycleManager() *lifecycleManager {
+	return &lifecycleManager{}
+}
+
+// register starts svc immediately and adds it to the managed set.
+func (m *lifecycleManager) register(ctx context.Context, svc Service) error {
+	if err := svc.Start(ctx); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.services = append(m.services, svc)
+	m.mu.Unlock()
+	return nil
+}
+
+// stopAll requests an orderly Stop of every managed service, in reverse
+// registration order, bounded by ctx's deadline (the configured
+// shutdown-timeout grace period).
+func (m *lifecycleManager) stopAll(ctx context.Context) {
+	m.mu.Lock()
+	services := append([]Service(nil), m.services...)
+	m.mu.Unlock()
+
+	for i := len(services) - 1; i >= 0; i-- {
+		_ = services[i].Stop(ctx)
+	}
+}
+
+// waitAll blocks until every managed service has fully stopped.
+func (m *lifecycleManager) waitAll() {
+	m.mu.Lock()
+	services := append([]Service(nil), m.services...)
+	m.mu.Unlock()
+
+	for _, svc := range services {
+		svc.Wait()
+	}
+}
@@ -0,0 +1,91 @@
+package blaster
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+)
+
+// openLogAndInit opens config.Log (if set) and writes the header row.
+// *csv.Writer already satisfies LogWriteFlusher (Write([]string) error,
+// Flush()), so it's used directly.
+func (b *Blaster) openLogAndInit() error {
+	if b.config.Log == "" {
+		return nil
+	}
+
+	f, err := os.Create(b.config.Log)
+	if err != nil {
+		return err
+	}
+	b.logCloser = f
+
+	writer := csv.NewWriter(f)
+	b.logWriter = writer
+
+	headers := append(append([]string{}, b.dataHeaders...), "output", "error")
+	return writer.Write(headers)
+}
+
+// flushAndCloseLog flushes and closes the log file opened by
+// openLogAndInit, if any.
+func (b *Blaster) flushAndCloseLog() {
+	if b.logWriter != nil {
+		b.logWriter.Flush()
+	}
+	if b.logCloser != nil {
+		_ = b.logCloser.Close()
+	}
+}
+
+// startLogLoop drains logChannel, writing one CSV row per logRecord, until
+// workersFinishedChannel fires and the channel is drained, or ctx is done.
+// It runs synchronously until then and is managed as a Service.
+func (b *Blaster) startLogLoop(ctx context.Context) {
+	for {
+		select {
+		case record, ok := <-b.logChannel:
+			if !ok {
+				return
+			}
+			b.writeLogRecord(record)
+
+		case <-b.workersFinishedChannel:
+			// Drain whatever's left without blocking on new work.
+			for {
+				select {
+				case record := <-b.logChannel:
+					b.writeLogRecord(record)
+				default:
+					return
+				}
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *Blaster) writeLogRecord(record logRecord) {
+	if b.logWriter == nil {
+		return
+	}
+
+	row := make([]string, 0, len(b.dataHeaders)+2)
+	for _, h := range b.dataHeaders {
+		row = append(row, fmt.Sprintf("%v", record.Input[h]))
+	}
+
+	row = append(row, fmt.Sprintf("%v", record.Output))
+	if record.Error != nil {
+		row = append(row, record.Error.Error())
+	} else {
+		row = append(row, "")
+	}
+
+	if err := b.logWriter.Write(row); err != nil {
+		b.errorChannel <- err
+	}
+}
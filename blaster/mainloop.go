@@ -0,0 +1,152 @@
+package blaster
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// rate returns the current send rate. It's written from startTickerLoop
+// and read concurrently by startRateControlLoop, metricsDef.Snapshot
+// (served to any number of /metrics and /stream HTTP clients) and
+// printStatus, so it's stored as raw bits behind atomic.Load/StoreUint64
+// rather than a plain float64.
+func (b *Blaster) rate() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&b.rateBits))
+}
+
+func (b *Blaster) setRate(rate float64) {
+	atomic.StoreUint64(&b.rateBits, math.Float64bits(rate))
+}
+
+// startTickerLoop paces dispatch: it signals mainChannel once per
+// 1/b.rate() seconds, and recomputes that interval whenever a new rate
+// arrives on changeRateChannel, whether that change came from stdin, the
+// status server's /rate, or the adaptive rate controller.
+func (b *Blaster) startTickerLoop(ctx context.Context) {
+	interval := rateToInterval(b.rate())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			select {
+			case b.mainChannel <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+		case rate := <-b.changeRateChannel:
+			b.setRate(rate)
+			b.metrics.addSegment(rate)
+			ticker.Stop()
+			ticker = time.NewTicker(rateToInterval(rate))
+
+		case <-b.workersFinishedChannel:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func rateToInterval(rate float64) time.Duration {
+	if rate <= 0 {
+		return time.Second
+	}
+	return time.Duration(float64(time.Second) / rate)
+}
+
+// startMainLoop reads one data record per mainChannel tick and dispatches
+// it to workerChannel (or, in batch mode, to the same channel where
+// startBatcherLoop picks it up instead of startWorkers). It closes
+// dataFinishedChannel once the data file is exhausted.
+func (b *Blaster) startMainLoop(ctx context.Context) {
+	attempt := 0
+
+	for {
+		select {
+		case <-b.mainChannel:
+			record, err := b.dataReader.Read()
+			if err != nil {
+				close(b.dataFinishedChannel)
+				return
+			}
+
+			payload := make(map[string]interface{}, len(b.dataHeaders))
+			for i, h := range b.dataHeaders {
+				if i < len(record) {
+					payload[h] = record[i]
+				}
+			}
+
+			attempt++
+			w := workDef{
+				WorkerType: b.config.Worker,
+				Payload:    payload,
+				Attempt:    attempt,
+			}
+
+			select {
+			case b.workerChannel <- w:
+			case <-ctx.Done():
+				return
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// startRateLoop reads lines of stdin, parses each as a new rate, and
+// pushes it through changeRateChannel, just like a POST to /rate does.
+func (b *Blaster) startRateLoop(ctx context.Context) {
+	scanner := bufio.NewScanner(b.rateInputReader)
+	lines := make(chan string)
+
+	go func() {
+		defer close(lines)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return
+			}
+			rate, err := strconv.ParseFloat(line, 64)
+			if err != nil {
+				fmt.Fprintf(b.out, "invalid rate %q: %v\n", line, err)
+				b.printRatePrompt()
+				continue
+			}
+			fmt.Fprintf(b.out, "rate changed to %v\n", rate)
+			b.changeRateChannel <- rate
+			b.printRatePrompt()
+
+		case <-b.workersFinishedChannel:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// printRatePrompt shows the current rate and reminds the operator how to
+// change it, either via stdin or, when running, the HTTP status server.
+func (b *Blaster) printRatePrompt() {
+	fmt.Fprintf(b.out, "Current rate: %v/s. Enter a new rate to change it", b.rate())
+	if b.config.StatusAddr != "" {
+		fmt.Fprintf(b.out, ", or POST to http://%s/rate", b.config.StatusAddr)
+	}
+	fmt.Fprintln(b.out, ".")
+}
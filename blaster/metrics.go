@@ -0,0 +1,188 @@
+package blaster
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// rateSegment records one period during which the rate was held constant,
+// so Snapshot (served at /metrics and /stream) can show the rate history
+// for a run, not just its current value.
+type rateSegment struct {
+	Rate      float64   `json:"rate"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// sendSample is one entry in metricsDef's rolling window.
+type sendSample struct {
+	latency time.Duration
+	isError bool
+}
+
+// windowSize bounds how many recent send latencies metricsDef keeps
+// around for p95/error-ratio sampling (used by the adaptive rate
+// controller and by /metrics). Older samples are dropped FIFO.
+const windowSize = 500
+
+// metricsDef is Blaster's running tally of what's happened so far: the
+// rate history, recent per-send latencies/errors for windowed sampling,
+// and batch-mode counters.
+type metricsDef struct {
+	b *Blaster
+
+	mu           sync.Mutex
+	segments     []rateSegment
+	window       []sendSample
+	windowErrors int
+
+	sendCount  uint64
+	errorCount uint64
+
+	batchQueued    map[string]*uint64
+	batchCount     uint64
+	batchItemCount uint64
+}
+
+func newMetricsDef(b *Blaster) *metricsDef {
+	return &metricsDef{
+		b:           b,
+		batchQueued: make(map[string]*uint64),
+	}
+}
+
+// addSegment records that the rate changed (or was first set) to rate.
+func (m *metricsDef) addSegment(rate float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.segments = append(m.segments, rateSegment{Rate: rate, StartedAt: time.Now()})
+}
+
+// recordSend adds one Worker.Send outcome to the rolling window used for
+// p95 latency / error ratio sampling, and to the lifetime counters shown
+// in Snapshot.
+func (m *metricsDef) recordSend(elapsed time.Duration, err error) {
+	atomic.AddUint64(&m.sendCount, 1)
+	if err != nil {
+		atomic.AddUint64(&m.errorCount, 1)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.window = append(m.window, sendSample{latency: elapsed, isError: err != nil})
+	if err != nil {
+		m.windowErrors++
+	}
+	if len(m.window) > windowSize {
+		if m.window[0].isError {
+			m.windowErrors--
+		}
+		m.window = m.window[1:]
+	}
+}
+
+// incBatchQueued tracks how many items have been queued for a given batch
+// worker type, for Snapshot.
+func (m *metricsDef) incBatchQueued(workerType string) {
+	m.mu.Lock()
+	counter, ok := m.batchQueued[workerType]
+	if !ok {
+		var c uint64
+		counter = &c
+		m.batchQueued[workerType] = counter
+	}
+	m.mu.Unlock()
+	atomic.AddUint64(counter, 1)
+}
+
+// recordBatch folds a dispatched batch's outcome into the same rolling
+// window recordSend uses, so a batch of n items contributes n latency
+// samples (each the batch's average per-item latency) rather than
+// skewing the window with one oversized sample.
+func (m *metricsDef) recordBatch(workerType string, n int, elapsed time.Duration, err error) {
+	atomic.AddUint64(&m.batchCount, 1)
+	atomic.AddUint64(&m.batchItemCount, uint64(n))
+
+	if n == 0 {
+		return
+	}
+	perItem := elapsed / time.Duration(n)
+	for i := 0; i < n; i++ {
+		m.recordSend(perItem, err)
+	}
+}
+
+// p95LatencyWindow returns the 95th percentile latency over the current
+// rolling window.
+func (m *metricsDef) p95LatencyWindow() time.Duration {
+	m.mu.Lock()
+	samples := make([]time.Duration, len(m.window))
+	for i, s := range m.window {
+		samples[i] = s.latency
+	}
+	m.mu.Unlock()
+
+	if len(samples) == 0 {
+		return 0
+	}
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	idx := int(float64(len(samples))*0.95) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(samples) {
+		idx = len(samples) - 1
+	}
+	return samples[idx]
+}
+
+// errorRatioWindow returns the fraction of sends in the current rolling
+// window that returned an error.
+func (m *metricsDef) errorRatioWindow() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.window) == 0 {
+		return 0
+	}
+	return float64(m.windowErrors) / float64(len(m.window))
+}
+
+// Snapshot returns a JSON-marshalable summary of current metrics, served
+// at /metrics and fed to the status server's periodic broadcast.
+func (m *metricsDef) Snapshot() interface{} {
+	m.mu.Lock()
+	segments := append([]rateSegment(nil), m.segments...)
+	batchQueued := make(map[string]uint64, len(m.batchQueued))
+	for workerType, counter := range m.batchQueued {
+		batchQueued[workerType] = atomic.LoadUint64(counter)
+	}
+	m.mu.Unlock()
+
+	return struct {
+		Rate           float64           `json:"rate"`
+		Segments       []rateSegment     `json:"segments"`
+		SendCount      uint64            `json:"sendCount"`
+		ErrorCount     uint64            `json:"errorCount"`
+		BatchCount     uint64            `json:"batchCount"`
+		BatchItemCount uint64            `json:"batchItemCount"`
+		BatchQueued    map[string]uint64 `json:"batchQueued"`
+		P95LatencyMS   float64           `json:"p95LatencyMs"`
+		ErrorRatio     float64           `json:"errorRatio"`
+		Timestamp      time.Time         `json:"timestamp"`
+	}{
+		Rate:           m.b.rate(),
+		Segments:       segments,
+		SendCount:      atomic.LoadUint64(&m.sendCount),
+		ErrorCount:     atomic.LoadUint64(&m.errorCount),
+		BatchCount:     atomic.LoadUint64(&m.batchCount),
+		BatchItemCount: atomic.LoadUint64(&m.batchItemCount),
+		BatchQueued:    batchQueued,
+		P95LatencyMS:   float64(m.p95LatencyWindow().Milliseconds()),
+		ErrorRatio:     m.errorRatioWindow(),
+		Timestamp:      time.Now(),
+	}
+}
@@ -0,0 +1,72 @@
+package blaster
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// buildOTLPTracerProvider dials endpoint over gRPC and returns a
+// sdktrace.TracerProvider sampling at ratio (0 < ratio <= 1, defaulting to
+// "always on" when ratio is 0), tagged with the given resource attributes
+// plus the blaster service name.
+func buildOTLPTracerProvider(endpoint string, ratio float64, resourceAttrs map[string]string) (*sdktrace.TracerProvider, error) {
+
+	exporter, err := otlptracegrpc.New(context.Background(), otlptracegrpc.WithEndpoint(endpoint), otlptracegrpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+
+	sampler := sdktrace.AlwaysSample()
+	if ratio > 0 && ratio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(ratio)
+	}
+
+	res, err := buildResource(resourceAttrs)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithResource(res),
+	), nil
+}
+
+// buildOTLPMeterProvider dials endpoint over gRPC and returns a
+// metric.MeterProvider that exports on a periodic reader.
+func buildOTLPMeterProvider(endpoint string, resourceAttrs map[string]string) (*metric.MeterProvider, error) {
+
+	exporter, err := otlpmetricgrpc.New(context.Background(), otlpmetricgrpc.WithEndpoint(endpoint), otlpmetricgrpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := buildResource(resourceAttrs)
+	if err != nil {
+		return nil, err
+	}
+
+	return metric.NewMeterProvider(
+		metric.WithReader(metric.NewPeriodicReader(exporter, metric.WithInterval(10*time.Second))),
+		metric.WithResource(res),
+	), nil
+}
+
+func buildResource(attrs map[string]string) (*resource.Resource, error) {
+	kvs := make([]attribute.KeyValue, 0, len(attrs)+1)
+	kvs = append(kvs, semconv.ServiceNameKey.String("blast"))
+	for k, v := range attrs {
+		kvs = append(kvs, attribute.String(strings.TrimSpace(k), v))
+	}
+	return resource.Merge(resource.Default(), resource.NewSchemaless(kvs...))
+}
@@ -0,0 +1,116 @@
+package blaster
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RateController is consulted once per control tick to decide whether the
+// current rate should change. The built-in implementation is aimdController;
+// embedders blasting endpoints that behave more like a concurrency-limited
+// system than a simple latency/error budget (e.g. Vegas-style concurrency
+// limits) can supply their own via Blaster.RateController.
+type RateController interface {
+	// Next returns the rate that should be in effect after this tick, given
+	// the current rate and the latency/error samples observed over the
+	// last control window. changed reports whether the rate should actually
+	// be pushed through changeRateChannel.
+	Next(current float64, window rateSample) (next float64, changed bool)
+}
+
+// rateSample summarizes worker behaviour over one control window, as
+// computed from metricsDef.
+type rateSample struct {
+	p95Latency time.Duration
+	errorRatio float64
+}
+
+// aimdController is the default RateController: an additive-increase,
+// multiplicative-decrease loop bounded by [Min, Max]. When error ratio and
+// p95 latency are both within budget it nudges the rate up by Increase;
+// the moment either budget is exceeded it backs off by multiplying the
+// rate by Factor (e.g. 0.7).
+type aimdController struct {
+	Min            float64
+	Max            float64
+	Increase       float64
+	Factor         float64
+	LatencyTarget  time.Duration
+	ErrorThreshold float64
+}
+
+func newAIMDController(config *configDef) *aimdController {
+	return &aimdController{
+		Min:            config.RateControlMin,
+		Max:            config.RateControlMax,
+		Increase:       config.RateControlIncrease,
+		Factor:         config.RateControlBackoffFactor,
+		LatencyTarget:  config.RateControlLatencyTarget,
+		ErrorThreshold: config.RateControlErrorThreshold,
+	}
+}
+
+func (a *aimdController) Next(current float64, window rateSample) (float64, bool) {
+
+	next := current
+
+	if window.errorRatio > a.ErrorThreshold || window.p95Latency > a.LatencyTarget {
+		next = current * a.Factor
+	} else {
+		next = current + a.Increase
+	}
+
+	if next < a.Min {
+		next = a.Min
+	}
+	if a.Max > 0 && next > a.Max {
+		next = a.Max
+	}
+
+	return next, next != current
+}
+
+// startRateControlLoop runs the adaptive rate controller (when
+// config.AdaptiveRate is set) on a one-second tick: it samples p95 latency
+// and error ratio from metrics over the last window and asks
+// b.RateController what the rate should be, pushing any change through
+// changeRateChannel exactly as a manual stdin/HTTP rate change would, so
+// startTickerLoop applies and records (as a new rate segment) changes from
+// either source the same way, and a manual override always wins until the
+// controller's next tick. The controller prints its own line for each
+// change it makes.
+func (b *Blaster) startRateControlLoop(ctx context.Context) {
+
+	if b.RateController == nil {
+		b.RateController = newAIMDController(b.config)
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			window := rateSample{
+				p95Latency: b.metrics.p95LatencyWindow(),
+				errorRatio: b.metrics.errorRatioWindow(),
+			}
+
+			next, changed := b.RateController.Next(b.rate(), window)
+			if !changed {
+				continue
+			}
+
+			fmt.Fprintf(b.out, "adaptive rate control: %.2f -> %.2f (p95=%s errors=%.1f%%)\n",
+				b.rate(), next, window.p95Latency, window.errorRatio*100)
+
+			b.changeRateChannel <- next
+
+		case <-b.workersFinishedChannel:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package blaster
+
+import (
+	"testing"
+	"time"
+)
+
+func testAIMDController() *aimdController {
+	return &aimdController{
+		Min:            1,
+		Max:            100,
+		Increase:       5,
+		Factor:         0.5,
+		LatencyTarget:  200 * time.Millisecond,
+		ErrorThreshold: 0.01,
+	}
+}
+
+func TestAIMDControllerIncreasesWithinBudget(t *testing.T) {
+	a := testAIMDController()
+
+	next, changed := a.Next(10, rateSample{p95Latency: 50 * time.Millisecond, errorRatio: 0})
+	if !changed {
+		t.Fatal("expected rate to change")
+	}
+	if next != 15 {
+		t.Fatalf("next = %v, want 15", next)
+	}
+}
+
+func TestAIMDControllerBacksOffOverErrorBudget(t *testing.T) {
+	a := testAIMDController()
+
+	next, changed := a.Next(10, rateSample{p95Latency: 50 * time.Millisecond, errorRatio: 0.5})
+	if !changed {
+		t.Fatal("expected rate to change")
+	}
+	if next != 5 {
+		t.Fatalf("next = %v, want 5", next)
+	}
+}
+
+func TestAIMDControllerBacksOffOverLatencyBudget(t *testing.T) {
+	a := testAIMDController()
+
+	next, changed := a.Next(10, rateSample{p95Latency: time.Second, errorRatio: 0})
+	if !changed {
+		t.Fatal("expected rate to change")
+	}
+	if next != 5 {
+		t.Fatalf("next = %v, want 5", next)
+	}
+}
+
+func TestAIMDControllerFloorsAtMin(t *testing.T) {
+	a := testAIMDController()
+
+	next, changed := a.Next(2, rateSample{p95Latency: time.Second, errorRatio: 1})
+	if !changed {
+		t.Fatal("expected rate to change")
+	}
+	if next != a.Min {
+		t.Fatalf("next = %v, want Min %v", next, a.Min)
+	}
+}
+
+func TestAIMDControllerCapsAtMax(t *testing.T) {
+	a := testAIMDController()
+
+	next, changed := a.Next(98, rateSample{p95Latency: 0, errorRatio: 0})
+	if !changed {
+		t.Fatal("expected rate to change")
+	}
+	if next != a.Max {
+		t.Fatalf("next = %v, want Max %v", next, a.Max)
+	}
+}
+
+func TestAIMDControllerNoChangeWhenRateUnchanged(t *testing.T) {
+	a := testAIMDController()
+	a.Max = 10 // current + Increase would exceed Max and clamp to it, but already at Max
+
+	_, changed := a.Next(10, rateSample{p95Latency: 0, errorRatio: 0})
+	if changed {
+		t.Fatal("expected no change once already at Max")
+	}
+}
@@ -0,0 +1,298 @@
+package blaster
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ringBuffer is a fixed-size, append-only history of the last N frames
+// (status snapshots or log lines, serialized to JSON). It exists so a
+// newly connected /stream subscriber can be replayed the recent past
+// before joining the live broadcast.
+type ringBuffer struct {
+	mu     sync.Mutex
+	frames [][]byte
+	size   int
+	next   int
+	full   bool
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{
+		frames: make([][]byte, size),
+		size:   size,
+	}
+}
+
+func (r *ringBuffer) add(frame []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.frames[r.next] = frame
+	r.next = (r.next + 1) % r.size
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// snapshot returns the buffered frames oldest-first.
+func (r *ringBuffer) snapshot() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		out := make([][]byte, r.next)
+		copy(out, r.frames[:r.next])
+		return out
+	}
+
+	out := make([][]byte, r.size)
+	copy(out, r.frames[r.next:])
+	copy(out[r.size-r.next:], r.frames[:r.next])
+	return out
+}
+
+// subscriberWriteTimeout bounds how long broadcast will wait for a slow
+// /stream subscriber before evicting it, so one stuck client can't stall
+// the status loop for everyone else.
+const subscriberWriteTimeout = 2 * time.Second
+
+// statusServer is the opt-in (--status-addr) HTTP endpoint that exposes
+// /metrics, /stream and /rate. It fans out status frames and log lines to
+// any number of subscribers via a WriteBroadcaster-style ring buffer: new
+// subscribers are replayed the buffered history on connect, then joined
+// to the live stream.
+type statusServer struct {
+	b *Blaster
+
+	statusRing *ringBuffer
+	logRing    *ringBuffer
+
+	mu          sync.Mutex
+	subscribers map[chan []byte]struct{}
+	running     bool
+	done        chan struct{}
+
+	srv *http.Server
+}
+
+// newStatusServer builds the opt-in HTTP status server for config.StatusAddr
+// with historySize frames of replay buffer. It is registered with
+// lifecycleManager as a Service, which calls Start/Wait/Stop on it.
+func newStatusServer(b *Blaster, historySize int) *statusServer {
+	if historySize <= 0 {
+		historySize = 100
+	}
+
+	s := &statusServer{
+		b:           b,
+		statusRing:  newRingBuffer(historySize),
+		logRing:     newRingBuffer(historySize),
+		subscribers: make(map[chan []byte]struct{}),
+	}
+	b.statusServer = s
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/stream", s.handleStream)
+	mux.HandleFunc("/rate", s.handleRate)
+
+	s.srv = &http.Server{
+		Addr:    b.config.StatusAddr,
+		Handler: mux,
+	}
+
+	return s
+}
+
+// Start implements Service by starting ListenAndServe in the background.
+func (s *statusServer) Start(ctx context.Context) error {
+	s.mu.Lock()
+	s.running = true
+	s.done = make(chan struct{})
+	s.mu.Unlock()
+
+	go func() {
+		defer close(s.done)
+		defer func() {
+			s.mu.Lock()
+			s.running = false
+			s.mu.Unlock()
+		}()
+		if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			s.b.errorChannel <- err
+		}
+	}()
+
+	return nil
+}
+
+// Wait implements Service, blocking until ListenAndServe has returned.
+func (s *statusServer) Wait() {
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+// Stop implements Service, shutting the HTTP server down within ctx's
+// deadline (the configured shutdown-timeout grace period) and evicting
+// every connected /stream subscriber.
+func (s *statusServer) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	for ch := range s.subscribers {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+	s.mu.Unlock()
+
+	return s.srv.Shutdown(ctx)
+}
+
+// IsRunning implements Service.
+func (s *statusServer) IsRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+func (s *statusServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	frame, err := json.Marshal(s.b.metrics.Snapshot())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(frame)
+}
+
+// handleStream serves an SSE stream: buffered history first, then live
+// status frames and log lines as they're published.
+func (s *statusServer) handleStream(w http.ResponseWriter, r *http.Request) {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	sub := s.subscribe()
+	defer s.unsubscribe(sub)
+
+	for _, frame := range s.statusRing.snapshot() {
+		writeSSEFrame(w, frame)
+	}
+	for _, frame := range s.logRing.snapshot() {
+		writeSSEFrame(w, frame)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case frame, ok := <-sub:
+			if !ok {
+				return
+			}
+			writeSSEFrame(w, frame)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func writeSSEFrame(w http.ResponseWriter, frame []byte) {
+	_, _ = w.Write([]byte("data: "))
+	_, _ = w.Write(frame)
+	_, _ = w.Write([]byte("\n\n"))
+}
+
+// handleRate replaces the stdin rate prompt for remote/CI observers: POST
+// a new rate and it's pushed through the existing changeRateChannel, so
+// manual stdin input and HTTP rate changes are handled identically.
+func (s *statusServer) handleRate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rate, err := strconv.ParseFloat(r.FormValue("rate"), 64)
+	if err != nil {
+		http.Error(w, "invalid rate: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.b.changeRateChannel <- rate
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// emitLogRecord sends record to logChannel as usual and, when the status
+// server is running, also publishes it to any connected /stream
+// subscribers. Call sites that previously wrote to b.logChannel directly
+// should use this instead so the live tail stays in sync.
+func (b *Blaster) emitLogRecord(record logRecord) {
+	b.logChannel <- record
+	if b.statusServer != nil {
+		b.statusServer.publishLog(record)
+	}
+}
+
+func (s *statusServer) subscribe() chan []byte {
+	ch := make(chan []byte, 16)
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+	return ch
+}
+
+func (s *statusServer) unsubscribe(ch chan []byte) {
+	s.mu.Lock()
+	delete(s.subscribers, ch)
+	s.mu.Unlock()
+}
+
+// publishStatus records a status snapshot in the ring buffer and fans it
+// out to every connected subscriber, evicting any that don't keep up
+// within subscriberWriteTimeout.
+func (s *statusServer) publishStatus(snapshot interface{}) {
+	frame, err := json.Marshal(snapshot)
+	if err != nil {
+		return
+	}
+	s.statusRing.add(frame)
+	s.broadcast(frame)
+}
+
+// publishLog mirrors publishStatus for individual log records.
+func (s *statusServer) publishLog(record logRecord) {
+	frame, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	s.logRing.add(frame)
+	s.broadcast(frame)
+}
+
+func (s *statusServer) broadcast(frame []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- frame:
+		case <-time.After(subscriberWriteTimeout):
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package blaster
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRingBufferSnapshotBeforeFull(t *testing.T) {
+	r := newRingBuffer(3)
+	r.add([]byte("a"))
+	r.add([]byte("b"))
+
+	got := r.snapshot()
+	want := [][]byte{[]byte("a"), []byte("b")}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("snapshot = %v, want %v", got, want)
+	}
+}
+
+func TestRingBufferSnapshotOldestFirstAfterWraparound(t *testing.T) {
+	r := newRingBuffer(3)
+	for _, frame := range []string{"a", "b", "c", "d", "e"} {
+		r.add([]byte(frame))
+	}
+
+	// Capacity 3, 5 frames written: oldest surviving is "c", newest is "e".
+	got := r.snapshot()
+	want := [][]byte{[]byte("c"), []byte("d"), []byte("e")}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("snapshot = %v, want %v", got, want)
+	}
+}
+
+func TestRingBufferSnapshotExactlyFull(t *testing.T) {
+	r := newRingBuffer(2)
+	r.add([]byte("a"))
+	r.add([]byte("b"))
+
+	got := r.snapshot()
+	want := [][]byte{[]byte("a"), []byte("b")}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("snapshot = %v, want %v", got, want)
+	}
+}
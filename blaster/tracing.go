@@ -0,0 +1,186 @@
+package blaster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentation holds the OTel handles Blaster uses to trace and measure
+// each Worker.Send call. It is populated lazily in start() from either the
+// providers injected via WithTracerProvider/WithMeterProvider, or from
+// configDef (OTLP endpoint, sampling rate, resource attributes) when no
+// provider was injected explicitly.
+type instrumentation struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+
+	tracer trace.Tracer
+
+	inFlight   metric.Int64UpDownCounter
+	latency    metric.Float64Histogram
+	errorCount metric.Int64Counter
+	sendCount  metric.Int64Counter
+}
+
+// WithTracerProvider lets an embedder supply its own OTel TracerProvider
+// (e.g. one already wired to an OTLP exporter) instead of the one Blaster
+// would otherwise build from configDef. Call before Start.
+func (b *Blaster) WithTracerProvider(tp trace.TracerProvider) *Blaster {
+	b.tracerProvider = tp
+	return b
+}
+
+// WithMeterProvider lets an embedder supply its own OTel MeterProvider.
+// Call before Start.
+func (b *Blaster) WithMeterProvider(mp metric.MeterProvider) *Blaster {
+	b.meterProvider = mp
+	return b
+}
+
+// setupInstrumentation resolves the tracer/meter providers (injected ones
+// take precedence over configDef-driven defaults) and creates the
+// instruments used by traceSend.
+func (b *Blaster) setupInstrumentation() error {
+
+	if b.tracerProvider == nil {
+		tp, err := newTracerProviderFromConfig(b.config)
+		if err != nil {
+			return fmt.Errorf("failed to configure OTel tracer provider: %w", err)
+		}
+		b.tracerProvider = tp
+	}
+
+	if b.meterProvider == nil {
+		mp, err := newMeterProviderFromConfig(b.config)
+		if err != nil {
+			return fmt.Errorf("failed to configure OTel meter provider: %w", err)
+		}
+		b.meterProvider = mp
+	}
+
+	b.instr = &instrumentation{
+		tracerProvider: b.tracerProvider,
+		meterProvider:  b.meterProvider,
+		tracer:         b.tracerProvider.Tracer("github.com/shammishailaj/blast"),
+	}
+
+	meter := b.meterProvider.Meter("github.com/shammishailaj/blast")
+
+	var err error
+	if b.instr.inFlight, err = meter.Int64UpDownCounter("blaster.worker.in_flight"); err != nil {
+		return err
+	}
+	if b.instr.latency, err = meter.Float64Histogram("blaster.worker.latency_ms"); err != nil {
+		return err
+	}
+	if b.instr.errorCount, err = meter.Int64Counter("blaster.worker.errors"); err != nil {
+		return err
+	}
+	if b.instr.sendCount, err = meter.Int64Counter("blaster.worker.sends"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// shutdownable is implemented by the concrete OTel SDK providers built by
+// newTracerProviderFromConfig/newMeterProviderFromConfig. The no-op
+// fallback providers don't implement it, so shutdownInstrumentation's type
+// assertions simply skip them.
+type shutdownable interface {
+	Shutdown(ctx context.Context) error
+}
+
+// shutdownInstrumentation flushes and closes whichever tracer/meter
+// providers are in use, whether injected via WithTracerProvider/
+// WithMeterProvider or built from configDef, so buffered spans/metrics
+// aren't dropped and the OTLP gRPC connection (if any) is closed cleanly.
+func (b *Blaster) shutdownInstrumentation(ctx context.Context) error {
+	if b.instr == nil {
+		return nil
+	}
+
+	if sp, ok := b.instr.tracerProvider.(shutdownable); ok {
+		if err := sp.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shut down tracer provider: %w", err)
+		}
+	}
+	if mp, ok := b.instr.meterProvider.(shutdownable); ok {
+		if err := mp.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shut down meter provider: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// traceSend wraps a single Worker.Send invocation in a span and records the
+// standard set of metrics (in-flight, latency, errors). It is the
+// integration point called from handleWork for every dispatched workDef;
+// the fn it wraps runs Starter.Start, Worker.Send and Stopper.Stop with the
+// span's context, so traces propagate downstream into user-defined workers.
+func (b *Blaster) traceSend(ctx context.Context, workerType string, payload map[string]interface{}, attempt int, fn func(context.Context) (map[string]interface{}, error)) (map[string]interface{}, error) {
+
+	if b.instr == nil {
+		return fn(ctx)
+	}
+
+	ctx, span := b.instr.tracer.Start(ctx, "blaster.worker.send", trace.WithAttributes(
+		attribute.String("blaster.worker.type", workerType),
+		attribute.StringSlice("blaster.payload.keys", payloadKeys(payload)),
+		attribute.Int("blaster.attempt", attempt),
+	))
+	defer span.End()
+
+	b.instr.inFlight.Add(ctx, 1)
+	defer b.instr.inFlight.Add(ctx, -1)
+
+	start := time.Now()
+	response, err := fn(ctx)
+	b.instr.latency.Record(ctx, float64(time.Since(start).Milliseconds()))
+	b.instr.sendCount.Add(ctx, 1)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetAttributes(attribute.String("blaster.status", "error"))
+		b.instr.errorCount.Add(ctx, 1)
+	} else {
+		span.SetAttributes(attribute.String("blaster.status", "ok"))
+	}
+
+	return response, err
+}
+
+func payloadKeys(payload map[string]interface{}) []string {
+	keys := make([]string, 0, len(payload))
+	for k := range payload {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// newTracerProviderFromConfig builds a TracerProvider from the OTLP
+// endpoint, sampling rate and resource attributes in configDef. When no
+// OTLP endpoint is configured it falls back to a no-op provider so tracing
+// stays zero-cost for users who don't opt in.
+func newTracerProviderFromConfig(config *configDef) (trace.TracerProvider, error) {
+	if config == nil || config.OTLPEndpoint == "" {
+		return trace.NewNoopTracerProvider(), nil
+	}
+	return buildOTLPTracerProvider(config.OTLPEndpoint, config.OTLPSamplingRatio, config.OTLPResourceAttributes)
+}
+
+// newMeterProviderFromConfig mirrors newTracerProviderFromConfig for
+// metrics, falling back to a no-op MeterProvider when unconfigured.
+func newMeterProviderFromConfig(config *configDef) (metric.MeterProvider, error) {
+	if config == nil || config.OTLPEndpoint == "" {
+		return noop.NewMeterProvider(), nil
+	}
+	return buildOTLPMeterProvider(config.OTLPEndpoint, config.OTLPResourceAttributes)
+}
@@ -0,0 +1,36 @@
+package blaster
+
+import "encoding/json"
+
+// workDef is a single unit of work read off the data file by
+// startMainLoop and handed to a worker (or, in batch mode, the batcher)
+// over workerChannel.
+type workDef struct {
+	WorkerType string
+	Payload    map[string]interface{}
+	Attempt    int
+}
+
+// logRecord is what startLogLoop writes out, one per workDef processed:
+// the payload that was sent, whatever the worker returned, and the error
+// if the send failed.
+type logRecord struct {
+	Input  map[string]interface{}
+	Output map[string]interface{}
+	Error  error
+}
+
+// MarshalJSON renders Error as its message so logRecord is safe to send to
+// /stream subscribers (the error interface's concrete type otherwise has
+// no exported fields for encoding/json to see).
+func (l logRecord) MarshalJSON() ([]byte, error) {
+	var errMsg string
+	if l.Error != nil {
+		errMsg = l.Error.Error()
+	}
+	return json.Marshal(struct {
+		Input  map[string]interface{} `json:"input"`
+		Output map[string]interface{} `json:"output"`
+		Error  string                 `json:"error,omitempty"`
+	}{l.Input, l.Output, errMsg})
+}
@@ -0,0 +1,136 @@
+package blaster
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// startWorkers is the default (non-batch) dispatch loop: one goroutine
+// per workDef read off workerChannel, each running the full
+// Starter/Send/Stopper lifecycle through traceSend and dedupedSend so
+// tracing, metrics and deduplication apply uniformly no matter which
+// worker type is registered.
+func (b *Blaster) startWorkers(ctx context.Context) {
+	for {
+		select {
+		case w, ok := <-b.workerChannel:
+			if !ok {
+				return
+			}
+			b.workerWait.Add(1)
+			go b.handleWork(ctx, w)
+
+		case <-b.workersFinishedChannel:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleWork runs one workDef through its registered Worker, wrapped by
+// the dedupe group and the tracing/metrics span, and emits the resulting
+// logRecord.
+func (b *Blaster) handleWork(ctx context.Context, w workDef) {
+	defer b.workerWait.Done()
+
+	newWorker, ok := b.workerTypes[w.WorkerType]
+	if !ok {
+		b.errorChannel <- fmt.Errorf("no worker registered for type %q", w.WorkerType)
+		return
+	}
+	worker := newWorker()
+
+	send := func(ctx context.Context) (map[string]interface{}, error) {
+		if starter, ok := worker.(Starter); ok {
+			if err := starter.Start(ctx, w.Payload); err != nil {
+				return nil, err
+			}
+		}
+
+		response, err := worker.Send(ctx, w.Payload)
+
+		if stopper, ok := worker.(Stopper); ok {
+			if stopErr := stopper.Stop(ctx, w.Payload); err == nil {
+				err = stopErr
+			}
+		}
+
+		return response, err
+	}
+
+	deduped := func(ctx context.Context) (map[string]interface{}, error) {
+		return b.dedupedSend(ctx, w.WorkerType, w.Payload, send)
+	}
+
+	start := time.Now()
+	response, err := b.traceSend(ctx, w.WorkerType, w.Payload, w.Attempt, deduped)
+	b.metrics.recordSend(time.Since(start), err)
+
+	b.emitLogRecord(logRecord{Input: w.Payload, Output: response, Error: err})
+}
+
+// startErrorLoop is the single place that decides whether an error from
+// any subsystem is fatal: the first one sets b.err and cancels ctx; any
+// that arrive afterwards (from goroutines still unwinding) are counted
+// in errorsIgnored rather than overwriting it.
+func (b *Blaster) startErrorLoop(ctx context.Context) {
+	for {
+		select {
+		case err, ok := <-b.errorChannel:
+			if !ok {
+				return
+			}
+			if b.err == nil {
+				b.err = err
+				b.cancel()
+			} else {
+				atomic.AddUint64(&b.errorsIgnored, 1)
+			}
+
+		case <-b.workersFinishedChannel:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// startStatusLoop prints a one-line status update once a second until
+// shutdown begins.
+func (b *Blaster) startStatusLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.printStatus(false)
+			if b.statusServer != nil {
+				b.statusServer.publishStatus(b.metrics.Snapshot())
+			}
+
+		case <-b.workersFinishedChannel:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// printStatus writes the current rate/throughput/error summary to b.out.
+// final adds a trailing newline, since the final status line is the last
+// thing start prints, after "All processes finished.".
+func (b *Blaster) printStatus(final bool) {
+	fmt.Fprintf(b.out, "\rrate=%.2f/s sent=%d errors=%d p95=%s",
+		b.rate(),
+		atomic.LoadUint64(&b.metrics.sendCount),
+		atomic.LoadUint64(&b.metrics.errorCount),
+		b.metrics.p95LatencyWindow(),
+	)
+	if final {
+		fmt.Fprintln(b.out)
+	}
+}